@@ -0,0 +1,248 @@
+/*
+*
+*  Mint, (C) 2021 Minio, Inc.
+*
+*  Licensed under the Apache License, Version 2.0 (the "License");
+*  you may not use this file except in compliance with the License.
+*  You may obtain a copy of the License at
+*
+*      http://www.apache.org/licenses/LICENSE-2.0
+*
+*  Unless required by applicable law or agreed to in writing, software
+
+*  distributed under the License is distributed on an "AS IS" BASIS,
+*  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+*  See the License for the specific language governing permissions and
+*  limitations under the License.
+*
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// cleanupPollInterval bounds how often cleanupBucket re-checks a
+// COMPLIANCE-locked version. cleanupMaxWait is the sane cap on how long it
+// will ever poll for one to expire: most COMPLIANCE retention windows used
+// by this test suite run from minutes to days, far longer than any test is
+// willing to block on, so a version whose RetainUntilDate falls beyond the
+// cap is left locked (and warned about) rather than polled to exhaustion.
+const (
+	cleanupPollInterval = 1 * time.Second
+	cleanupMaxWait      = 5 * time.Second
+	cleanupMaxRetries   = 3
+)
+
+// cleanupBucket empties and removes bucket, unlocking legal holds along the
+// way and waiting on COMPLIANCE locked versions that expire within
+// cleanupMaxWait; versions locked for longer are left behind with a warning.
+func cleanupBucket(bucket, function string, args map[string]interface{}, startTime time.Time) {
+	cleanupBucketOpts(bucket, function, args, startTime, false)
+}
+
+// forceCleanupBucket is the same as cleanupBucket, except it never blocks
+// on a version it cannot remove (e.g. a COMPLIANCE lock that has not yet
+// expired): it logs a warning and leaves the bucket behind instead, so the
+// caller can choose to leak rather than stall the whole test run.
+func forceCleanupBucket(bucket, function string, args map[string]interface{}, startTime time.Time) {
+	cleanupBucketOpts(bucket, function, args, startTime, true)
+}
+
+// cleanupBucketOpts lists every version and delete marker in bucket,
+// clears any legal holds it finds, waits out COMPLIANCE retention that is
+// about to expire (unless forceCleanup is set), retries transient errors
+// and finally removes the now-empty bucket.
+func cleanupBucketOpts(bucket, function string, args map[string]interface{}, startTime time.Time, forceCleanup bool) {
+	var keyMarker, versionIDMarker *string
+	for {
+		var listOutput *s3.ListObjectVersionsOutput
+		err := withRetry(func() (err error) {
+			listOutput, err = s3Client.ListObjectVersions(&s3.ListObjectVersionsInput{
+				Bucket:          aws.String(bucket),
+				KeyMarker:       keyMarker,
+				VersionIdMarker: versionIDMarker,
+			})
+			return err
+		})
+		if err != nil {
+			failureLog(function, args, startTime, "", "ListObjectVersions failed during cleanup", err).Fatal()
+			return
+		}
+
+		for _, version := range listOutput.Versions {
+			cleanupVersion(bucket, *version.Key, *version.VersionId, function, args, startTime, forceCleanup)
+		}
+		for _, marker := range listOutput.DeleteMarkers {
+			cleanupVersion(bucket, *marker.Key, *marker.VersionId, function, args, startTime, forceCleanup)
+		}
+
+		if !aws.BoolValue(listOutput.IsTruncated) {
+			break
+		}
+		keyMarker = listOutput.NextKeyMarker
+		versionIDMarker = listOutput.NextVersionIdMarker
+	}
+
+	err := withRetry(func() error {
+		_, err := s3Client.DeleteBucket(&s3.DeleteBucketInput{Bucket: aws.String(bucket)})
+		return err
+	})
+	if err != nil {
+		// A version we deliberately left locked (see cleanupVersion) keeps
+		// the bucket non-empty; that's an expected outcome of this test's
+		// retention policy, not a cleanup bug, so warn rather than fail
+		// the whole run.
+		fmt.Fprintf(os.Stderr, "warning: %s: could not remove bucket %s: %v\n", function, bucket, err)
+	}
+}
+
+// cleanupVersion removes any legal hold on key/versionId and deletes the
+// version, bypassing GOVERNANCE retention along the way. A lock that still
+// blocks the delete is waited out only if it expires within cleanupMaxWait
+// and forceCleanup is not set; otherwise the version is left with a warning.
+func cleanupVersion(bucket, key, versionID, function string, args map[string]interface{}, startTime time.Time, forceCleanup bool) {
+	var legalHold *s3.GetObjectLegalHoldOutput
+	err := withRetry(func() (err error) {
+		legalHold, err = s3Client.GetObjectLegalHold(&s3.GetObjectLegalHoldInput{
+			Bucket:    aws.String(bucket),
+			Key:       aws.String(key),
+			VersionId: aws.String(versionID),
+		})
+		return err
+	})
+	// Buckets without object lock enabled, or delete markers, do not carry
+	// a legal hold; ignore the resulting error and move on.
+	if err == nil && legalHold.LegalHold != nil && aws.StringValue(legalHold.LegalHold.Status) == s3.ObjectLockLegalHoldStatusOn {
+		err = withRetry(func() error {
+			_, err := s3Client.PutObjectLegalHold(&s3.PutObjectLegalHoldInput{
+				Bucket:    aws.String(bucket),
+				Key:       aws.String(key),
+				VersionId: aws.String(versionID),
+				LegalHold: &s3.ObjectLockLegalHold{
+					Status: aws.String(s3.ObjectLockLegalHoldStatusOff),
+				},
+			})
+			return err
+		})
+		if err != nil {
+			if forceCleanup {
+				fmt.Fprintf(os.Stderr, "warning: %s: could not clear legal hold on %s (version %s): %v\n", function, key, versionID, err)
+				return
+			}
+			failureLog(function, args, startTime, "", "PutObjectLegalHold failed during cleanup", err).Fatal()
+			return
+		}
+	}
+
+	err = withRetry(func() error {
+		_, err := s3Client.DeleteObject(&s3.DeleteObjectInput{
+			Bucket:                    aws.String(bucket),
+			Key:                       aws.String(key),
+			VersionId:                 aws.String(versionID),
+			BypassGovernanceRetention: aws.Bool(true),
+		})
+		return err
+	})
+	if err == nil {
+		return
+	}
+	if !isObjectLockedError(err) {
+		fmt.Fprintf(os.Stderr, "warning: %s: could not remove %s (version %s): %v\n", function, key, versionID, err)
+		return
+	}
+
+	// The version is still locked. Find out how long it would actually
+	// take to come free: a GOVERNANCE lock or a near-expired COMPLIANCE
+	// lock is worth a short, bounded wait, but most COMPLIANCE windows in
+	// this suite run from minutes to days, so anything past cleanupMaxWait
+	// (or any lock at all, under forceCleanup) is left behind with a
+	// warning instead of stalling the test run.
+	var retainUntil time.Time
+	var retentionOutput *s3.GetObjectRetentionOutput
+	retErr := withRetry(func() (err error) {
+		retentionOutput, err = s3Client.GetObjectRetention(&s3.GetObjectRetentionInput{
+			Bucket:    aws.String(bucket),
+			Key:       aws.String(key),
+			VersionId: aws.String(versionID),
+		})
+		return err
+	})
+	if retErr == nil && retentionOutput.Retention != nil {
+		retainUntil = aws.TimeValue(retentionOutput.Retention.RetainUntilDate)
+	}
+
+	if forceCleanup || retainUntil.IsZero() || time.Until(retainUntil) > cleanupMaxWait {
+		fmt.Fprintf(os.Stderr, "warning: %s: leaving %s (version %s) locked, not removed: %v\n", function, key, versionID, err)
+		return
+	}
+
+	backoff := cleanupPollInterval
+	for time.Now().Before(retainUntil) {
+		time.Sleep(backoff)
+		if backoff < cleanupPollInterval*8 {
+			backoff *= 2
+		}
+	}
+
+	err = withRetry(func() error {
+		_, err := s3Client.DeleteObject(&s3.DeleteObjectInput{
+			Bucket:                    aws.String(bucket),
+			Key:                       aws.String(key),
+			VersionId:                 aws.String(versionID),
+			BypassGovernanceRetention: aws.Bool(true),
+		})
+		return err
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s: could not remove %s (version %s) after waiting out its retention: %v\n", function, key, versionID, err)
+	}
+}
+
+// isObjectLockedError reports whether err looks like a retryable
+// "still locked" rejection rather than a permanent failure.
+func isObjectLockedError(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case "AccessDenied", "InvalidObjectState":
+			return true
+		}
+	}
+	return false
+}
+
+// withRetry calls fn up to cleanupMaxRetries times in all, retrying on transient
+// (throttling/network) errors, with a short linear backoff between
+// attempts.
+func withRetry(fn func() error) error {
+	var err error
+	for attempt := 0; attempt < cleanupMaxRetries; attempt++ {
+		err = fn()
+		if err == nil || !isTransientError(err) {
+			return err
+		}
+		time.Sleep(time.Duration(attempt+1) * 200 * time.Millisecond)
+	}
+	return err
+}
+
+// isTransientError reports whether err is worth retrying, such as
+// throttling or a request timeout, as opposed to a permanent rejection.
+func isTransientError(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	switch aerr.Code() {
+	case "RequestTimeout", "SlowDown", "Throttling", "ThrottlingException", "InternalError", "ServiceUnavailable":
+		return true
+	}
+	return false
+}
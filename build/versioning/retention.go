@@ -482,3 +482,828 @@ func testPutGetDeleteLockingRetention(function, retentionMode string) {
 
 	successLogger(function, args, startTime).Info()
 }
+
+// Test put, get and delete of the object legal hold, independent of any
+// retention mode.
+func testPutGetDeleteLegalHold() {
+	startTime := time.Now()
+	function := "testPutGetDeleteLegalHold"
+	bucket := randString(60, rand.NewSource(time.Now().UnixNano()), "versioning-test-")
+	object := "testObject"
+	args := map[string]interface{}{
+		"bucketName": bucket,
+		"objectName": object,
+	}
+
+	_, err := s3Client.CreateBucket(&s3.CreateBucketInput{
+		Bucket:                     aws.String(bucket),
+		ObjectLockEnabledForBucket: aws.Bool(true),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotImplemented: A header you provided implies functionality that is not implemented") {
+			ignoreLog(function, args, startTime, "Versioning is not implemented").Info()
+			return
+		}
+		failureLog(function, args, startTime, "", "CreateBucket failed", err).Fatal()
+		return
+	}
+
+	defer cleanupBucket(bucket, function, args, startTime)
+
+	// Upload a version with the legal hold turned on
+	putInput := &s3.PutObjectInput{
+		Body:                      aws.ReadSeekCloser(strings.NewReader("content")),
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(object),
+		ObjectLockLegalHoldStatus: aws.String(s3.ObjectLockLegalHoldStatusOn),
+	}
+
+	output, err := s3Client.PutObject(putInput)
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PUT expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+	versionId := *output.VersionId
+
+	getLegalHoldInput := &s3.GetObjectLegalHoldInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(object),
+		VersionId: aws.String(versionId),
+	}
+
+	legalHoldOutput, err := s3Client.GetObjectLegalHold(getLegalHoldInput)
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("GetObjectLegalHold expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	if *legalHoldOutput.LegalHold.Status != s3.ObjectLockLegalHoldStatusOn {
+		failureLog(function, args, startTime, "", "Unexpected legal hold status", nil).Fatal()
+		return
+	}
+
+	// While the hold is ON, deleting the version must fail
+	deleteInput := &s3.DeleteObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(object),
+		VersionId: aws.String(versionId),
+	}
+	_, err = s3Client.DeleteObject(deleteInput)
+	if err == nil {
+		failureLog(function, args, startTime, "", "DELETE expected to fail but succeeded instead", nil).Fatal()
+		return
+	}
+
+	// Turn the hold OFF and the version should now be removable
+	putLegalHoldInput := &s3.PutObjectLegalHoldInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(object),
+		VersionId: aws.String(versionId),
+		LegalHold: &s3.ObjectLockLegalHold{
+			Status: aws.String(s3.ObjectLockLegalHoldStatusOff),
+		},
+	}
+	_, err = s3Client.PutObjectLegalHold(putLegalHoldInput)
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PutObjectLegalHold expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	_, err = s3Client.DeleteObject(deleteInput)
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("DELETE expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	successLogger(function, args, startTime).Info()
+}
+
+// Test that a legal hold set independently via PutObjectLegalHold on an
+// already-uploaded version blocks deletion of that version, and that
+// clearing it unblocks deletion again.
+func testLegalHoldBlocksDelete() {
+	startTime := time.Now()
+	function := "testLegalHoldBlocksDelete"
+	bucket := randString(60, rand.NewSource(time.Now().UnixNano()), "versioning-test-")
+	object := "testObject"
+	args := map[string]interface{}{
+		"bucketName": bucket,
+		"objectName": object,
+	}
+
+	_, err := s3Client.CreateBucket(&s3.CreateBucketInput{
+		Bucket:                     aws.String(bucket),
+		ObjectLockEnabledForBucket: aws.Bool(true),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotImplemented: A header you provided implies functionality that is not implemented") {
+			ignoreLog(function, args, startTime, "Versioning is not implemented").Info()
+			return
+		}
+		failureLog(function, args, startTime, "", "CreateBucket failed", err).Fatal()
+		return
+	}
+
+	defer cleanupBucket(bucket, function, args, startTime)
+
+	// Upload a plain version, without any legal hold header
+	putInput := &s3.PutObjectInput{
+		Body:   aws.ReadSeekCloser(strings.NewReader("content")),
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object),
+	}
+
+	output, err := s3Client.PutObject(putInput)
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PUT expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+	versionId := *output.VersionId
+
+	// Apply the legal hold after the fact
+	putLegalHoldInput := &s3.PutObjectLegalHoldInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(object),
+		VersionId: aws.String(versionId),
+		LegalHold: &s3.ObjectLockLegalHold{
+			Status: aws.String(s3.ObjectLockLegalHoldStatusOn),
+		},
+	}
+	_, err = s3Client.PutObjectLegalHold(putLegalHoldInput)
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PutObjectLegalHold expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	deleteInput := &s3.DeleteObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(object),
+		VersionId: aws.String(versionId),
+	}
+	_, err = s3Client.DeleteObject(deleteInput)
+	if err == nil {
+		failureLog(function, args, startTime, "", "DELETE expected to fail but succeeded instead", nil).Fatal()
+		return
+	}
+
+	// Remove the legal hold and retry the delete
+	putLegalHoldInput.LegalHold.Status = aws.String(s3.ObjectLockLegalHoldStatusOff)
+	_, err = s3Client.PutObjectLegalHold(putLegalHoldInput)
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PutObjectLegalHold expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	_, err = s3Client.DeleteObject(deleteInput)
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("DELETE expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	successLogger(function, args, startTime).Info()
+}
+
+// Test that a legal hold keeps a version undeletable even once a
+// COMPLIANCE retention's RetainUntilDate has already passed, and that
+// clearing the legal hold alone (without waiting on retention) unblocks it.
+func testLegalHoldWithRetentionCombined() {
+	startTime := time.Now()
+	function := "testLegalHoldWithRetentionCombined"
+	bucket := randString(60, rand.NewSource(time.Now().UnixNano()), "versioning-test-")
+	object := "testObject"
+	args := map[string]interface{}{
+		"bucketName": bucket,
+		"objectName": object,
+	}
+
+	_, err := s3Client.CreateBucket(&s3.CreateBucketInput{
+		Bucket:                     aws.String(bucket),
+		ObjectLockEnabledForBucket: aws.Bool(true),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotImplemented: A header you provided implies functionality that is not implemented") {
+			ignoreLog(function, args, startTime, "Versioning is not implemented").Info()
+			return
+		}
+		failureLog(function, args, startTime, "", "CreateBucket failed", err).Fatal()
+		return
+	}
+
+	defer cleanupBucket(bucket, function, args, startTime)
+
+	// A short-lived COMPLIANCE retention combined with a legal hold
+	retentionUntil := time.Now().UTC().Add(2 * time.Second)
+	putInput := &s3.PutObjectInput{
+		Body:                      aws.ReadSeekCloser(strings.NewReader("content")),
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(object),
+		ObjectLockMode:            aws.String("COMPLIANCE"),
+		ObjectLockRetainUntilDate: aws.Time(retentionUntil),
+		ObjectLockLegalHoldStatus: aws.String(s3.ObjectLockLegalHoldStatusOn),
+	}
+
+	output, err := s3Client.PutObject(putInput)
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PUT expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+	versionId := *output.VersionId
+
+	// Wait for the retention window to elapse; the legal hold alone
+	// must still prevent the version from being deleted.
+	time.Sleep(3 * time.Second)
+
+	deleteInput := &s3.DeleteObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(object),
+		VersionId: aws.String(versionId),
+	}
+	_, err = s3Client.DeleteObject(deleteInput)
+	if err == nil {
+		failureLog(function, args, startTime, "", "DELETE expected to fail but succeeded instead", nil).Fatal()
+		return
+	}
+
+	// Clear the legal hold; retention has already expired so the
+	// version should now be deletable.
+	putLegalHoldInput := &s3.PutObjectLegalHoldInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(object),
+		VersionId: aws.String(versionId),
+		LegalHold: &s3.ObjectLockLegalHold{
+			Status: aws.String(s3.ObjectLockLegalHoldStatusOff),
+		},
+	}
+	_, err = s3Client.PutObjectLegalHold(putLegalHoldInput)
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PutObjectLegalHold expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	_, err = s3Client.DeleteObject(deleteInput)
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("DELETE expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	successLogger(function, args, startTime).Info()
+}
+
+func testDefaultRetentionGovernanceDays() {
+	testDefaultBucketRetention("testDefaultRetentionGovernanceDays", "GOVERNANCE", "Days", 1)
+}
+
+func testDefaultRetentionGovernanceYears() {
+	testDefaultBucketRetention("testDefaultRetentionGovernanceYears", "GOVERNANCE", "Years", 1)
+}
+
+func testDefaultRetentionComplianceDays() {
+	testDefaultBucketRetention("testDefaultRetentionComplianceDays", "COMPLIANCE", "Days", 1)
+}
+
+func testDefaultRetentionComplianceYears() {
+	testDefaultBucketRetention("testDefaultRetentionComplianceYears", "COMPLIANCE", "Years", 1)
+}
+
+// Test that a bucket-level DefaultRetention rule is inherited by objects
+// uploaded without an explicit ObjectLockMode header.
+func testDefaultBucketRetention(function, retentionMode, unit string, amount int64) {
+	startTime := time.Now()
+	bucket := randString(60, rand.NewSource(time.Now().UnixNano()), "versioning-test-")
+	object := "testObject"
+	args := map[string]interface{}{
+		"bucketName":    bucket,
+		"objectName":    object,
+		"retentionMode": retentionMode,
+		"unit":          unit,
+		"amount":        amount,
+	}
+
+	_, err := s3Client.CreateBucket(&s3.CreateBucketInput{
+		Bucket:                     aws.String(bucket),
+		ObjectLockEnabledForBucket: aws.Bool(true),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotImplemented: A header you provided implies functionality that is not implemented") {
+			ignoreLog(function, args, startTime, "Versioning is not implemented").Info()
+			return
+		}
+		failureLog(function, args, startTime, "", "CreateBucket failed", err).Fatal()
+		return
+	}
+
+	defer cleanupBucket(bucket, function, args, startTime)
+
+	defaultRetention := &s3.DefaultRetention{
+		Mode: aws.String(retentionMode),
+	}
+	var expectedUntil time.Time
+	switch unit {
+	case "Days":
+		defaultRetention.Days = aws.Int64(amount)
+		expectedUntil = time.Now().UTC().AddDate(0, 0, int(amount))
+	case "Years":
+		defaultRetention.Years = aws.Int64(amount)
+		expectedUntil = time.Now().UTC().AddDate(int(amount), 0, 0)
+	}
+
+	_, err = s3Client.PutObjectLockConfiguration(&s3.PutObjectLockConfigurationInput{
+		Bucket: aws.String(bucket),
+		ObjectLockConfiguration: &s3.ObjectLockConfiguration{
+			ObjectLockEnabled: aws.String(s3.ObjectLockEnabledEnabled),
+			Rule: &s3.ObjectLockRule{
+				DefaultRetention: defaultRetention,
+			},
+		},
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PutObjectLockConfiguration expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	getConfigOutput, err := s3Client.GetObjectLockConfiguration(&s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("GetObjectLockConfiguration expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+	if *getConfigOutput.ObjectLockConfiguration.Rule.DefaultRetention.Mode != retentionMode {
+		failureLog(function, args, startTime, "", "Unexpected default retention mode", nil).Fatal()
+		return
+	}
+
+	// Upload without any ObjectLockMode header; the bucket default must apply
+	putInput := &s3.PutObjectInput{
+		Body:   aws.ReadSeekCloser(strings.NewReader("content")),
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object),
+	}
+	output, err := s3Client.PutObject(putInput)
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PUT expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+	versionId := *output.VersionId
+
+	retentionOutput, err := s3Client.GetObjectRetention(&s3.GetObjectRetentionInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(object),
+		VersionId: aws.String(versionId),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("GetObjectRetention expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	if *retentionOutput.Retention.Mode != retentionMode {
+		failureLog(function, args, startTime, "", "Unexpected inherited retention mode", nil).Fatal()
+		return
+	}
+
+	// Allow a day of slack since the exact DefaultRetention -> RetainUntilDate
+	// rounding is implementation-defined
+	if retentionOutput.Retention.RetainUntilDate.Before(expectedUntil.Add(-24 * time.Hour)) {
+		failureLog(function, args, startTime, "", "Inherited RetainUntilDate is earlier than the bucket default", nil).Fatal()
+		return
+	}
+
+	successLogger(function, args, startTime).Info()
+}
+
+// Test that PutObjectLockConfiguration rejects a DefaultRetention rule that
+// specifies both Days and Years.
+func testObjectLockConfigurationRejectsDaysAndYears() {
+	startTime := time.Now()
+	function := "testObjectLockConfigurationRejectsDaysAndYears"
+	bucket := randString(60, rand.NewSource(time.Now().UnixNano()), "versioning-test-")
+	args := map[string]interface{}{
+		"bucketName": bucket,
+	}
+
+	_, err := s3Client.CreateBucket(&s3.CreateBucketInput{
+		Bucket:                     aws.String(bucket),
+		ObjectLockEnabledForBucket: aws.Bool(true),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotImplemented: A header you provided implies functionality that is not implemented") {
+			ignoreLog(function, args, startTime, "Versioning is not implemented").Info()
+			return
+		}
+		failureLog(function, args, startTime, "", "CreateBucket failed", err).Fatal()
+		return
+	}
+
+	defer cleanupBucket(bucket, function, args, startTime)
+
+	_, err = s3Client.PutObjectLockConfiguration(&s3.PutObjectLockConfigurationInput{
+		Bucket: aws.String(bucket),
+		ObjectLockConfiguration: &s3.ObjectLockConfiguration{
+			ObjectLockEnabled: aws.String(s3.ObjectLockEnabledEnabled),
+			Rule: &s3.ObjectLockRule{
+				DefaultRetention: &s3.DefaultRetention{
+					Mode:  aws.String("GOVERNANCE"),
+					Days:  aws.Int64(1),
+					Years: aws.Int64(1),
+				},
+			},
+		},
+	})
+	if err == nil {
+		failureLog(function, args, startTime, "", "PutObjectLockConfiguration expected to fail but succeeded", nil).Fatal()
+		return
+	}
+
+	successLogger(function, args, startTime).Info()
+}
+
+// Test that PutObjectLockConfiguration rejects enabling object lock on a
+// bucket that was never created with object lock (and thus versioning)
+// enabled.
+func testObjectLockConfigurationRequiresVersioning() {
+	startTime := time.Now()
+	function := "testObjectLockConfigurationRequiresVersioning"
+	bucket := randString(60, rand.NewSource(time.Now().UnixNano()), "versioning-test-")
+	args := map[string]interface{}{
+		"bucketName": bucket,
+	}
+
+	_, err := s3Client.CreateBucket(&s3.CreateBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", "CreateBucket failed", err).Fatal()
+		return
+	}
+
+	defer cleanupBucket(bucket, function, args, startTime)
+
+	_, err = s3Client.PutObjectLockConfiguration(&s3.PutObjectLockConfigurationInput{
+		Bucket: aws.String(bucket),
+		ObjectLockConfiguration: &s3.ObjectLockConfiguration{
+			ObjectLockEnabled: aws.String(s3.ObjectLockEnabledEnabled),
+			Rule: &s3.ObjectLockRule{
+				DefaultRetention: &s3.DefaultRetention{
+					Mode: aws.String("GOVERNANCE"),
+					Days: aws.Int64(1),
+				},
+			},
+		},
+	})
+	if err == nil {
+		failureLog(function, args, startTime, "", "PutObjectLockConfiguration expected to fail but succeeded", nil).Fatal()
+		return
+	}
+
+	successLogger(function, args, startTime).Info()
+}
+
+// Test that DeleteObject on a GOVERNANCE-locked version only succeeds when
+// BypassGovernanceRetention is set, and that COMPLIANCE-locked versions can
+// never be bypassed this way.
+func testDeleteObjectBypassGovernanceRetention() {
+	startTime := time.Now()
+	function := "testDeleteObjectBypassGovernanceRetention"
+	bucket := randString(60, rand.NewSource(time.Now().UnixNano()), "versioning-test-")
+	object := "testObject"
+	args := map[string]interface{}{
+		"bucketName": bucket,
+		"objectName": object,
+	}
+
+	_, err := s3Client.CreateBucket(&s3.CreateBucketInput{
+		Bucket:                     aws.String(bucket),
+		ObjectLockEnabledForBucket: aws.Bool(true),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotImplemented: A header you provided implies functionality that is not implemented") {
+			ignoreLog(function, args, startTime, "Versioning is not implemented").Info()
+			return
+		}
+		failureLog(function, args, startTime, "", "CreateBucket failed", err).Fatal()
+		return
+	}
+
+	// The COMPLIANCE object created in assertion (c) below is deliberately
+	// left locked for an hour to verify it can never be bypass-deleted;
+	// force cleanup leaves it behind with a warning, without any wait.
+	defer forceCleanupBucket(bucket, function, args, startTime)
+
+	retentionUntil := time.Now().UTC().Add(time.Hour)
+
+	putGovernanceVersion := func() string {
+		output, err := s3Client.PutObject(&s3.PutObjectInput{
+			Body:                      aws.ReadSeekCloser(strings.NewReader("content")),
+			Bucket:                    aws.String(bucket),
+			Key:                       aws.String(object),
+			ObjectLockMode:            aws.String("GOVERNANCE"),
+			ObjectLockRetainUntilDate: aws.Time(retentionUntil),
+		})
+		if err != nil {
+			failureLog(function, args, startTime, "", fmt.Sprintf("PUT expected to succeed but got %v", err), err).Fatal()
+			return ""
+		}
+		return *output.VersionId
+	}
+
+	// (b) Without the bypass header, the delete must be rejected
+	versionId := putGovernanceVersion()
+	_, err = s3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(object),
+		VersionId: aws.String(versionId),
+	})
+	if err == nil {
+		failureLog(function, args, startTime, "", "DELETE without bypass header expected to fail but succeeded", nil).Fatal()
+		return
+	}
+
+	// (a) With the bypass header, the delete must succeed
+	_, err = s3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(object),
+		VersionId:                 aws.String(versionId),
+		BypassGovernanceRetention: aws.Bool(true),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("DELETE with bypass header expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	// (c) COMPLIANCE-locked versions must never be bypassable
+	complianceOutput, err := s3Client.PutObject(&s3.PutObjectInput{
+		Body:                      aws.ReadSeekCloser(strings.NewReader("content")),
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(object),
+		ObjectLockMode:            aws.String("COMPLIANCE"),
+		ObjectLockRetainUntilDate: aws.Time(retentionUntil),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PUT expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+	_, err = s3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(object),
+		VersionId:                 complianceOutput.VersionId,
+		BypassGovernanceRetention: aws.Bool(true),
+	})
+	if err == nil {
+		failureLog(function, args, startTime, "", "DELETE of COMPLIANCE version with bypass header expected to fail but succeeded", nil).Fatal()
+		return
+	}
+
+	successLogger(function, args, startTime).Info()
+}
+
+// Test that DeleteObjects (multi-object delete) honors
+// BypassGovernanceRetention per object and reports a per-object
+// success/error breakdown, rather than silently ignoring the header.
+func testDeleteObjectsBypassGovernanceRetention() {
+	startTime := time.Now()
+	function := "testDeleteObjectsBypassGovernanceRetention"
+	bucket := randString(60, rand.NewSource(time.Now().UnixNano()), "versioning-test-")
+	governanceObject := "governanceObject"
+	complianceObject := "complianceObject"
+	plainObject := "plainObject"
+	args := map[string]interface{}{
+		"bucketName": bucket,
+	}
+
+	_, err := s3Client.CreateBucket(&s3.CreateBucketInput{
+		Bucket:                     aws.String(bucket),
+		ObjectLockEnabledForBucket: aws.Bool(true),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotImplemented: A header you provided implies functionality that is not implemented") {
+			ignoreLog(function, args, startTime, "Versioning is not implemented").Info()
+			return
+		}
+		failureLog(function, args, startTime, "", "CreateBucket failed", err).Fatal()
+		return
+	}
+
+	// The COMPLIANCE object below is deliberately left locked for an hour
+	// to verify it survives the batch delete; force cleanup leaves it
+	// behind with a warning, without any wait.
+	defer forceCleanupBucket(bucket, function, args, startTime)
+
+	retentionUntil := time.Now().UTC().Add(time.Hour)
+
+	governanceOutput, err := s3Client.PutObject(&s3.PutObjectInput{
+		Body:                      aws.ReadSeekCloser(strings.NewReader("content")),
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(governanceObject),
+		ObjectLockMode:            aws.String("GOVERNANCE"),
+		ObjectLockRetainUntilDate: aws.Time(retentionUntil),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PUT expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	complianceOutput, err := s3Client.PutObject(&s3.PutObjectInput{
+		Body:                      aws.ReadSeekCloser(strings.NewReader("content")),
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(complianceObject),
+		ObjectLockMode:            aws.String("COMPLIANCE"),
+		ObjectLockRetainUntilDate: aws.Time(retentionUntil),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PUT expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	plainOutput, err := s3Client.PutObject(&s3.PutObjectInput{
+		Body:   aws.ReadSeekCloser(strings.NewReader("content")),
+		Bucket: aws.String(bucket),
+		Key:    aws.String(plainObject),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PUT expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	deleteObjectsOutput, err := s3Client.DeleteObjects(&s3.DeleteObjectsInput{
+		Bucket: aws.String(bucket),
+		Delete: &s3.Delete{
+			Objects: []*s3.ObjectIdentifier{
+				{Key: aws.String(governanceObject), VersionId: governanceOutput.VersionId},
+				{Key: aws.String(complianceObject), VersionId: complianceOutput.VersionId},
+				{Key: aws.String(plainObject), VersionId: plainOutput.VersionId},
+			},
+		},
+		BypassGovernanceRetention: aws.Bool(true),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("DeleteObjects expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	if len(deleteObjectsOutput.Deleted) != 2 {
+		failureLog(function, args, startTime, "", fmt.Sprintf("Expected 2 objects deleted, got %d", len(deleteObjectsOutput.Deleted)), nil).Fatal()
+		return
+	}
+	if len(deleteObjectsOutput.Errors) != 1 {
+		failureLog(function, args, startTime, "", fmt.Sprintf("Expected 1 object to error out, got %d", len(deleteObjectsOutput.Errors)), nil).Fatal()
+		return
+	}
+	if *deleteObjectsOutput.Errors[0].Key != complianceObject {
+		failureLog(function, args, startTime, "", "Expected the COMPLIANCE-locked object to be the one reported as an error", nil).Fatal()
+		return
+	}
+
+	// The COMPLIANCE version must still be present
+	_, err = s3Client.GetObjectRetention(&s3.GetObjectRetentionInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(complianceObject),
+		VersionId: complianceOutput.VersionId,
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("GetObjectRetention expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	successLogger(function, args, startTime).Info()
+}
+
+// Test enforcement of the s3:object-lock-remaining-retention-days bucket
+// policy condition key against the BypassGovernanceRetention permission,
+// closing the gap between the retention header path and IAM policy
+// evaluation.
+func testObjectLockRemainingRetentionDaysPolicyCondition() {
+	startTime := time.Now()
+	function := "testObjectLockRemainingRetentionDaysPolicyCondition"
+	bucket := randString(60, rand.NewSource(time.Now().UnixNano()), "versioning-test-")
+	object := "testObject"
+	thresholdDays := 5
+	args := map[string]interface{}{
+		"bucketName":    bucket,
+		"objectName":    object,
+		"thresholdDays": thresholdDays,
+	}
+
+	_, err := s3Client.CreateBucket(&s3.CreateBucketInput{
+		Bucket:                     aws.String(bucket),
+		ObjectLockEnabledForBucket: aws.Bool(true),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotImplemented: A header you provided implies functionality that is not implemented") {
+			ignoreLog(function, args, startTime, "Versioning is not implemented").Info()
+			return
+		}
+		failureLog(function, args, startTime, "", "CreateBucket failed", err).Fatal()
+		return
+	}
+
+	// The bucket policy installed below denies BypassGovernanceRetention
+	// for any version with more than thresholdDays remaining, which would
+	// also deny cleanupBucket's own bypass delete; force cleanup so that,
+	// if the test exits before the policy is removed, a version the backend
+	// refuses to delete is left behind with a warning, without any wait.
+	defer forceCleanupBucket(bucket, function, args, startTime)
+
+	// Deny BypassGovernanceRetention whenever more than thresholdDays of
+	// retention remain on the version being acted on.
+	policy := fmt.Sprintf(`{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Sid": "DenyBypassAboveRemainingRetentionThreshold",
+			"Effect": "Deny",
+			"Principal": "*",
+			"Action": "s3:BypassGovernanceRetention",
+			"Resource": "arn:aws:s3:::%s/*",
+			"Condition": {
+				"NumericGreaterThan": {
+					"s3:object-lock-remaining-retention-days": "%d"
+				}
+			}
+		}]
+	}`, bucket, thresholdDays)
+
+	_, err = s3Client.PutBucketPolicy(&s3.PutBucketPolicyInput{
+		Bucket: aws.String(bucket),
+		Policy: aws.String(policy),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PutBucketPolicy expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	// Upload a version with 10 days of GOVERNANCE retention: remaining
+	// days exceeds the threshold, so the bypassed delete must be denied.
+	longRetention := time.Now().UTC().AddDate(0, 0, 10)
+	longOutput, err := s3Client.PutObject(&s3.PutObjectInput{
+		Body:                      aws.ReadSeekCloser(strings.NewReader("content")),
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(object),
+		ObjectLockMode:            aws.String("GOVERNANCE"),
+		ObjectLockRetainUntilDate: aws.Time(longRetention),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PUT expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	_, err = s3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(object),
+		VersionId:                 longOutput.VersionId,
+		BypassGovernanceRetention: aws.Bool(true),
+	})
+	if err == nil {
+		failureLog(function, args, startTime, "", "DELETE with bypass expected to be denied by policy while remaining retention is high but succeeded", nil).Fatal()
+		return
+	}
+
+	// Re-upload with a retention window inside the threshold: remaining
+	// days is now below the threshold, so the bypassed delete must be
+	// permitted.
+	shortRetention := time.Now().UTC().AddDate(0, 0, thresholdDays-2)
+	shortOutput, err := s3Client.PutObject(&s3.PutObjectInput{
+		Body:                      aws.ReadSeekCloser(strings.NewReader("content")),
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(object),
+		ObjectLockMode:            aws.String("GOVERNANCE"),
+		ObjectLockRetainUntilDate: aws.Time(shortRetention),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("PUT expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	_, err = s3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket:                    aws.String(bucket),
+		Key:                       aws.String(object),
+		VersionId:                 shortOutput.VersionId,
+		BypassGovernanceRetention: aws.Bool(true),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("DELETE with bypass expected to be permitted once remaining retention dropped below the threshold but got %v", err), err).Fatal()
+		return
+	}
+
+	// The long-retention version must still be intact and locked
+	_, err = s3Client.GetObjectRetention(&s3.GetObjectRetentionInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(object),
+		VersionId: longOutput.VersionId,
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("GetObjectRetention expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	// The assertions above are done with the deny policy in place; remove
+	// it now so it doesn't also deny the deferred cleanup's own bypass
+	// delete of the long-retention version.
+	_, err = s3Client.DeleteBucketPolicy(&s3.DeleteBucketPolicyInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		failureLog(function, args, startTime, "", fmt.Sprintf("DeleteBucketPolicy expected to succeed but got %v", err), err).Fatal()
+		return
+	}
+
+	successLogger(function, args, startTime).Info()
+}
@@ -0,0 +1,240 @@
+/*
+*
+*  Mint, (C) 2021 Minio, Inc.
+*
+*  Licensed under the Apache License, Version 2.0 (the "License");
+*  you may not use this file except in compliance with the License.
+*  You may obtain a copy of the License at
+*
+*      http://www.apache.org/licenses/LICENSE-2.0
+*
+*  Unless required by applicable law or agreed to in writing, software
+
+*  distributed under the License is distributed on an "AS IS" BASIS,
+*  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+*  See the License for the specific language governing permissions and
+*  limitations under the License.
+*
+ */
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// retentionStressOpHistogram accumulates per-operation latencies and error
+// counts observed by the stress workers of testObjectLockRetentionStress.
+type retentionStressOpHistogram struct {
+	mu         sync.Mutex
+	latencies  []time.Duration
+	errorCount int64
+}
+
+func (h *retentionStressOpHistogram) record(d time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.latencies = append(h.latencies, d)
+	if err != nil {
+		h.errorCount++
+	}
+}
+
+func (h *retentionStressOpHistogram) summary() (count int, errorCount int64, p50, p99, max time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	count = len(h.latencies)
+	errorCount = h.errorCount
+	if count == 0 {
+		return
+	}
+	sorted := append([]time.Duration(nil), h.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	p50 = sorted[count*50/100]
+	p99 = sorted[count*99/100]
+	max = sorted[count-1]
+	return
+}
+
+// Test Object Lock retention, legal hold and bypass-delete invariants under
+// concurrent load. Only runs when MINT_MODE=stress, since it trades the
+// single-threaded happy-path coverage of retention.go for a soak that can
+// shake out races in the retention/versioning code paths.
+//
+// This lives in its own file, rather than alongside the other Object Lock
+// tests in retention.go, because it brings its own concurrency machinery
+// (the histogram type above, the worker pool and env-var configuration
+// below) that has nothing to do with that file's single-threaded,
+// request/response style tests.
+//
+// Workers and duration are configurable via STRESS_WORKERS (default 10) and
+// STRESS_DURATION_SECONDS (default 30).
+func testObjectLockRetentionStress() {
+	startTime := time.Now()
+	function := "testObjectLockRetentionStress"
+	bucket := randString(60, rand.NewSource(time.Now().UnixNano()), "versioning-test-")
+	args := map[string]interface{}{
+		"bucketName": bucket,
+	}
+
+	if os.Getenv("MINT_MODE") != "stress" {
+		ignoreLog(function, args, startTime, "Skipping stress test; set MINT_MODE=stress to run").Info()
+		return
+	}
+
+	workers := 10
+	if v := os.Getenv("STRESS_WORKERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			workers = n
+		}
+	}
+	duration := 30 * time.Second
+	if v := os.Getenv("STRESS_DURATION_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			duration = time.Duration(n) * time.Second
+		}
+	}
+	args["workers"] = workers
+	args["duration"] = duration
+
+	_, err := s3Client.CreateBucket(&s3.CreateBucketInput{
+		Bucket:                     aws.String(bucket),
+		ObjectLockEnabledForBucket: aws.Bool(true),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "NotImplemented: A header you provided implies functionality that is not implemented") {
+			ignoreLog(function, args, startTime, "Versioning is not implemented").Info()
+			return
+		}
+		failureLog(function, args, startTime, "", "CreateBucket failed", err).Fatal()
+		return
+	}
+	defer cleanupBucket(bucket, function, args, startTime)
+
+	object := "stressObject"
+
+	histograms := map[string]*retentionStressOpHistogram{
+		"putWithRetention":   {},
+		"putObjectRetention": {},
+		"putObjectLegalHold": {},
+		"bypassDelete":       {},
+	}
+
+	var invariantViolation error
+	var violationOnce sync.Once
+	deadline := time.Now().Add(duration)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			src := rand.NewSource(time.Now().UnixNano() + int64(worker))
+			r := rand.New(src)
+
+			for time.Now().Before(deadline) {
+				mode := "GOVERNANCE"
+				if r.Intn(2) == 0 {
+					mode = "COMPLIANCE"
+				}
+				retainUntil := time.Now().UTC().Add(time.Duration(1+r.Intn(5)) * time.Minute)
+
+				opStart := time.Now()
+				output, err := s3Client.PutObject(&s3.PutObjectInput{
+					Body:                      aws.ReadSeekCloser(strings.NewReader("content")),
+					Bucket:                    aws.String(bucket),
+					Key:                       aws.String(object),
+					ObjectLockMode:            aws.String(mode),
+					ObjectLockRetainUntilDate: aws.Time(retainUntil),
+				})
+				histograms["putWithRetention"].record(time.Since(opStart), err)
+				if err != nil {
+					continue
+				}
+				versionId := *output.VersionId
+
+				// Randomly extend the retention window. The invariant
+				// check below must compare against this extended date,
+				// not the one the version was originally uploaded with.
+				if r.Intn(2) == 0 {
+					extendedRetainUntil := retainUntil.Add(time.Minute)
+					opStart = time.Now()
+					_, err = s3Client.PutObjectRetention(&s3.PutObjectRetentionInput{
+						Bucket:    aws.String(bucket),
+						Key:       aws.String(object),
+						VersionId: aws.String(versionId),
+						Retention: &s3.ObjectLockRetention{
+							Mode:            aws.String(mode),
+							RetainUntilDate: aws.Time(extendedRetainUntil),
+						},
+					})
+					histograms["putObjectRetention"].record(time.Since(opStart), err)
+					if err == nil {
+						retainUntil = extendedRetainUntil
+					}
+				}
+
+				// Randomly toggle a legal hold
+				holdOn := r.Intn(2) == 0
+				status := s3.ObjectLockLegalHoldStatusOff
+				if holdOn {
+					status = s3.ObjectLockLegalHoldStatusOn
+				}
+				opStart = time.Now()
+				_, err = s3Client.PutObjectLegalHold(&s3.PutObjectLegalHoldInput{
+					Bucket:    aws.String(bucket),
+					Key:       aws.String(object),
+					VersionId: aws.String(versionId),
+					LegalHold: &s3.ObjectLockLegalHold{
+						Status: aws.String(status),
+					},
+				})
+				histograms["putObjectLegalHold"].record(time.Since(opStart), err)
+
+				// Attempt a bypassed delete; this must never succeed for a
+				// COMPLIANCE version whose RetainUntilDate is still in the
+				// future, regardless of the legal hold toggle above.
+				opStart = time.Now()
+				_, deleteErr := s3Client.DeleteObject(&s3.DeleteObjectInput{
+					Bucket:                    aws.String(bucket),
+					Key:                       aws.String(object),
+					VersionId:                 aws.String(versionId),
+					BypassGovernanceRetention: aws.Bool(true),
+				})
+				histograms["bypassDelete"].record(time.Since(opStart), deleteErr)
+
+				if deleteErr == nil && mode == "COMPLIANCE" && time.Now().Before(retainUntil) {
+					violationOnce.Do(func() {
+						invariantViolation = fmt.Errorf(
+							"version %s was deleted via bypass while COMPLIANCE retention was still active (until %s)",
+							versionId, retainUntil)
+					})
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if invariantViolation != nil {
+		failureLog(function, args, startTime, "", "Invariant violated during stress run", invariantViolation).Fatal()
+		return
+	}
+
+	for op, h := range histograms {
+		count, errCount, p50, p99, max := h.summary()
+		args[op] = fmt.Sprintf("count=%d errors=%d p50=%s p99=%s max=%s", count, errCount, p50, p99, max)
+	}
+
+	successLogger(function, args, startTime).Info()
+}